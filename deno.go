@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/sha1"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,8 +16,10 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"text/template"
 	"time"
@@ -23,25 +27,187 @@ import (
 	"github.com/pkg/errors"
 )
 
+// invocationIDHeader carries a per-Invoke correlation ID that
+// function_server.ts echoes back on structured log events it emits while
+// handling that request.
+const invocationIDHeader = "X-Invocation-Id"
+
+func newInvocationID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+const (
+	// TransportUnix runs each worker's HTTP server on a Unix domain socket
+	// inside the instance's temp dir. Default on non-Windows platforms:
+	// no port to scan for or expose, and the socket file is only reachable
+	// by whoever can see inst.tempDir.
+	TransportUnix = "unix"
+	// TransportTCP runs each worker's HTTP server on a loopback TCP port
+	// found via FindFreePort. Kept around for Windows and anyone who needs it.
+	TransportTCP = "tcp"
+)
+
 type Config struct {
 	WorkDir  string
 	DenoPath string
+
+	// Concurrency is the number of Deno worker processes spun up per
+	// DenoFunctionInstance. Invoke dispatches across them so calls can run
+	// in parallel instead of queueing behind one another. Defaults to 1,
+	// which preserves the old one-process-at-a-time behavior.
+	//
+	// Each unit of Concurrency is a full "deno run" OS process with its own
+	// V8 heap, not a lightweight in-process worker: raising it multiplies
+	// process-start cost and idle memory by that amount. For a handler
+	// that's mostly waiting on I/O, a smaller Concurrency than the expected
+	// number of simultaneous callers is usually enough to saturate it.
+	Concurrency int
+
+	// Transport selects how Invoke talks to the worker processes: "unix"
+	// (default on non-Windows) or "tcp". See TransportUnix/TransportTCP.
+	Transport string
+
+	// Permissions controls the Deno permission flags the worker processes
+	// are started with. The zero value grants nothing beyond what's needed
+	// to serve requests, which is more restrictive than the old hard-coded
+	// --allow-net --allow-env --unstable.
+	Permissions Permissions
+
+	// Limits bounds the resources a worker process may use.
+	Limits Limits
+
+	// ShutdownTimeout bounds how long Close() waits for a worker to exit
+	// after SIGTERM before escalating to SIGKILL. Defaults to 5 seconds.
+	ShutdownTimeout time.Duration
+
+	// LogHandler receives a structured LogEvent for every line a worker
+	// writes to stdout/stderr, in addition to whatever func(string)
+	// callback was passed to NewDenoFunctionInstance.
+	LogHandler func(LogEvent)
+}
+
+// Permissions mirrors Deno's granular --allow-* flags. Each slice field is a
+// list of allowed values; an empty/nil list denies that capability outright
+// rather than falling back to "allow everything". Unstable adds --unstable.
+type Permissions struct {
+	AllowNetHosts   []string
+	AllowEnv        []string
+	AllowReadPaths  []string
+	AllowWritePaths []string
+	AllowRun        []string
+	Unstable        bool
+}
+
+// Limits bounds what a single worker process may consume.
+type Limits struct {
+	// MaxOldSpaceMB caps the V8 heap, rendered as --v8-flags=--max-old-space-size=N.
+	MaxOldSpaceMB int
+
+	// Timeout bounds a single Invoke call. Enforced on the Go side via
+	// context, not a Deno flag, so it applies regardless of transport.
+	Timeout time.Duration
+
+	// MaxAddressSpaceMB and MaxCPUSeconds are enforced as RLIMIT_AS /
+	// RLIMIT_CPU on the worker process. Linux only (see deno_linux.go);
+	// no-ops on other platforms.
+	MaxAddressSpaceMB int
+	MaxCPUSeconds     int
+}
+
+// denoRunArgs renders the `deno run ...` argument list for one worker,
+// translating Permissions/Limits into the corresponding Deno flags and
+// granting whatever extra access the chosen transport needs to serve
+// requests (e.g. read/write on the unix socket's directory).
+func denoRunArgs(config *Config, denoDir, transport, address, netAddr string) []string {
+	perm := config.Permissions
+
+	args := []string{"run"}
+	if perm.Unstable {
+		args = append(args, "--unstable")
+	}
+
+	netHosts := append([]string{}, perm.AllowNetHosts...)
+	if transport == TransportTCP {
+		netHosts = append(netHosts, netAddr)
+	}
+	if len(netHosts) > 0 {
+		args = append(args, "--allow-net="+strings.Join(netHosts, ","))
+	}
+
+	if len(perm.AllowEnv) > 0 {
+		args = append(args, "--allow-env="+strings.Join(perm.AllowEnv, ","))
+	}
+
+	readPaths := append([]string{}, perm.AllowReadPaths...)
+	writePaths := append([]string{}, perm.AllowWritePaths...)
+	if transport == TransportUnix {
+		// The server needs to create (and later remove) its socket file.
+		readPaths = append(readPaths, denoDir)
+		writePaths = append(writePaths, denoDir)
+	}
+	if len(readPaths) > 0 {
+		args = append(args, "--allow-read="+strings.Join(readPaths, ","))
+	}
+	if len(writePaths) > 0 {
+		args = append(args, "--allow-write="+strings.Join(writePaths, ","))
+	}
+
+	if len(perm.AllowRun) > 0 {
+		args = append(args, "--allow-run="+strings.Join(perm.AllowRun, ","))
+	}
+
+	if config.Limits.MaxOldSpaceMB > 0 {
+		args = append(args, fmt.Sprintf("--v8-flags=--max-old-space-size=%d", config.Limits.MaxOldSpaceMB))
+	}
+
+	args = append(args, fmt.Sprintf("%s/function_server.ts", denoDir), transport, address)
+	return args
 }
 
+func (c *Config) transport() string {
+	if c.Transport != "" {
+		return c.Transport
+	}
+	if runtime.GOOS == "windows" {
+		return TransportTCP
+	}
+	return TransportUnix
+}
+
+// denoWorker is a single Deno OS process backing an instance. Instances
+// with Config.Concurrency > 1 run several of these side by side.
+type denoWorker struct {
+	cmd       *exec.Cmd
+	client    *http.Client
+	serverURL string
+	inflight  int32 // atomic: requests currently being served by this worker
+
+	exited  chan struct{} // closed once cmd.Wait() returns
+	exitErr error         // valid only after exited is closed
+	dead    int32         // atomic bool: set once exited is observed, so leastBusyWorker stops selecting it
+}
 
 // ======= Function ============
 type DenoFunctionInstance struct {
-	config      *Config
-	cmd         *exec.Cmd
-	lastInvoked time.Time
-	runLock     sync.Mutex
-	serverURL   string
-	tempDir     string
-	denoExited  chan error
+	config          *Config
+	lastInvokedNano int64 // atomic: UnixNano, written by every acquireWorker call, read by LastInvoked
+	tempDir         string
+
+	workers    []*denoWorker
+	nextWorker uint64 // atomic round-robin cursor, used as a tie-breaker
+	sem        chan struct{}
+	denoExited chan error
+
+	closing   int32      // atomic bool, set by Close() to reject new Invoke calls
+	closingMu sync.Mutex // guards closing+inflight.Add so Close can't finish draining before a racing acquireWorker registers
+	inflight  sync.WaitGroup
+	closeOnce sync.Once
 }
 
 func (inst *DenoFunctionInstance) LastInvoked() time.Time {
-	return inst.lastInvoked
+	return time.Unix(0, atomic.LoadInt64(&inst.lastInvokedNano))
 }
 
 func (inst *DenoFunctionInstance) DidExit() chan error {
@@ -100,8 +266,15 @@ func newFunctionHash(code string) functionHash {
 }
 
 func NewDenoFunctionInstance(ctx context.Context, config *Config, logCallback func(message string), initData interface{}, code string) (*DenoFunctionInstance, error) {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
 	inst := &DenoFunctionInstance{
-		config: config,
+		config:          config,
+		sem:             make(chan struct{}, concurrency),
+		lastInvokedNano: time.Now().UnixNano(),
 	}
 
 	// Create deno project for function
@@ -119,49 +292,113 @@ func NewDenoFunctionInstance(ctx context.Context, config *Config, logCallback fu
 		return nil, errors.Wrap(err, "write JS function file")
 	}
 
-	// Find an available TCP port to bind the function server to
-	listenPort := FindFreePort(8000)
+	// This is the point where we have subprocesses running which we may want
+	// to kill if we don't boot successfully. This will be set to true at the
+	// end, if it's not set, some error occurred along the way.
+	everythingOk := false
+	defer func() {
+		if !everythingOk {
+			inst.Close()
+		}
+	}()
 
-	// Run deno as child process with only network and environment variable access
-	inst.cmd = exec.Command(config.DenoPath, "run", "--unstable", "--allow-net", "--allow-env", fmt.Sprintf("%s/function_server.ts", denoDir), fmt.Sprintf("%d", listenPort))
+	for i := 0; i < concurrency; i++ {
+		worker, err := startDenoWorker(ctx, config, denoDir, i, logCallback)
+		if err != nil {
+			return nil, errors.Wrapf(err, "start worker %d", i)
+		}
+		inst.workers = append(inst.workers, worker)
+	}
+
+	// Fires as soon as any one worker exits, so existing single-process
+	// callers of DidExit() keep working unchanged.
+	inst.denoExited = make(chan error, 1)
+	for _, worker := range inst.workers {
+		worker := worker
+		go func() {
+			<-worker.exited
+			atomic.StoreInt32(&worker.dead, 1)
+			select {
+			case inst.denoExited <- worker.exitErr:
+			default:
+			}
+		}()
+	}
+
+	everythingOk = true
+
+	return inst, nil
+}
+
+// startDenoWorker launches a single Deno subprocess running function_server.ts
+// against the already-prepared function.js in denoDir, and waits for its HTTP
+// server to come up before returning.
+func startDenoWorker(ctx context.Context, config *Config, denoDir string, index int, logCallback func(message string)) (*denoWorker, error) {
+	worker := &denoWorker{}
+
+	// address is what's passed to function_server.ts; dialAddr is what we
+	// use locally to probe readiness and (for tcp) to connect.
+	var address, dialAddr string
+	switch config.transport() {
+	case TransportUnix:
+		socketPath := fmt.Sprintf("%s/func-%d.sock", denoDir, index)
+		address = socketPath
+		dialAddr = socketPath
+		worker.serverURL = "http://unix"
+		worker.client = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		}
+	default:
+		listenPort := FindFreePort(8000)
+		address = fmt.Sprintf("%d", listenPort)
+		dialAddr = fmt.Sprintf("127.0.0.1:%d", listenPort)
+		worker.serverURL = fmt.Sprintf("http://127.0.0.1:%d", listenPort)
+		worker.client = http.DefaultClient
+	}
+
+	// Run deno as a child process with only the configured permissions
+	runArgs := denoRunArgs(config, denoDir, config.transport(), address, dialAddr)
+	worker.cmd = exec.Command(config.DenoPath, runArgs...)
+	applyResourceLimits(worker.cmd, config.Limits)
 
 	// Don't propagate Ctrl-c to children
-	inst.cmd.SysProcAttr = &syscall.SysProcAttr{
+	worker.cmd.SysProcAttr = &syscall.SysProcAttr{
 		Setpgid: true,
 	}
-	inst.cmd.Env = append(inst.cmd.Env,
+	worker.cmd.Env = append(worker.cmd.Env,
 		"NO_COLOR=1",
 		fmt.Sprintf("DENO_DIR=%s/.deno/cache", config.WorkDir))
 
-	stdoutPipe, err := inst.cmd.StdoutPipe()
+	stdoutPipe, err := worker.cmd.StdoutPipe()
 	if err != nil {
 		return nil, errors.Wrap(err, "stdout pipe")
 	}
-	stderrPipe, err := inst.cmd.StderrPipe()
+	stderrPipe, err := worker.cmd.StderrPipe()
 	if err != nil {
 		return nil, errors.Wrap(err, "stderr pipe")
 	}
 
 	// Kick off the command in the background
-	// Making it buffered to prevent go-routine leak (we don't care for the result after initial start-up)
-	inst.denoExited = make(chan error, 1)
-	if err := inst.cmd.Start(); err != nil {
+	worker.exited = make(chan struct{})
+	if err := worker.cmd.Start(); err != nil {
 		return nil, errors.Wrap(err, "deno run")
 	}
-	//log.Errorf("STARTING %s", name)
 
-	// This is the point where we have a subprocess running which we may want to kill if we don't boot successfully
-	// This will be set to true at the end, if it's not set, some error occured along the way
 	everythingOk := false
 	defer func() {
-		if !everythingOk && inst.cmd.Process != nil {
-			//log.Info("Hard killing deno process because of error")
-			inst.Close()
+		if !everythingOk && worker.cmd.Process != nil {
+			worker.cmd.Process.Kill()
 		}
 	}()
 
 	go func() {
-		inst.denoExited <- inst.cmd.Wait()
+		worker.exitErr = worker.cmd.Wait()
+		close(worker.exited)
 	}()
 
 	// Listen to the stderr and log pipes and ship everything to logChannel
@@ -169,10 +406,16 @@ func NewDenoFunctionInstance(ctx context.Context, config *Config, logCallback fu
 	bufferedStderr := bufio.NewReader(stderrPipe)
 
 	// Send stdout and stderr to the log channel
-	go pipeLogStreamToCallback(bufferedStdout, logCallback)
-	go pipeLogStreamToCallback(bufferedStderr, logCallback)
-
-	inst.serverURL = fmt.Sprintf("http://localhost:%d", listenPort)
+	handleLogEvent := func(event LogEvent) {
+		if config.LogHandler != nil {
+			config.LogHandler(event)
+		}
+		if logCallback != nil {
+			logCallback(event.Message)
+		}
+	}
+	go pipeLogStream(LogStreamStdout, bufferedStdout, handleLogEvent)
+	go pipeLogStream(LogStreamStderr, bufferedStderr, handleLogEvent)
 
 	// Wait for server to come up
 waitLoop:
@@ -180,12 +423,13 @@ waitLoop:
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-inst.denoExited:
+		case <-worker.exited:
 			return nil, errors.New("deno exited on boot")
 		default:
 		}
-		_, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", listenPort))
+		conn, err := net.Dial(config.transport(), dialAddr)
 		if err == nil {
+			conn.Close()
 			break waitLoop
 		}
 		time.Sleep(100 * time.Millisecond)
@@ -193,44 +437,186 @@ waitLoop:
 
 	everythingOk = true
 
-	return inst, nil
+	return worker, nil
 }
 
-// Somewhat cleanly stop the deno process and clean up the temporary source files
+const defaultShutdownTimeout = 5 * time.Second
+
+// ErrClosing is returned by Invoke once Close has been called on the
+// instance; no new calls are accepted while a shutdown is in progress.
+var ErrClosing = errors.New("instance is closing")
+
+// Close blocks new Invoke calls, waits for in-flight ones to finish, then
+// gives each worker a chance to shut down cleanly: SIGTERM its process
+// group, wait up to Config.ShutdownTimeout for it to exit, and only then
+// SIGKILL. Finally the temporary source directory is removed.
 func (inst *DenoFunctionInstance) Close() {
-	if inst.cmd.Process != nil {
-		inst.cmd.Process.Kill()
+	inst.closeOnce.Do(func() {
+		inst.closingMu.Lock()
+		atomic.StoreInt32(&inst.closing, 1)
+		inst.closingMu.Unlock()
+		inst.inflight.Wait()
+
+		timeout := inst.config.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = defaultShutdownTimeout
+		}
+
+		var wg sync.WaitGroup
+		for _, worker := range inst.workers {
+			worker := worker
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				shutdownWorker(worker, timeout)
+			}()
+		}
+		wg.Wait()
+
+		if err := os.RemoveAll(inst.tempDir); err != nil {
+			fmt.Printf("Could not delete directory %s: %s\n", inst.tempDir, err)
+		}
+	})
+}
+
+// shutdownWorker sends SIGTERM to the worker's process group and waits up
+// to timeout for it to exit before escalating to SIGKILL.
+func shutdownWorker(worker *denoWorker, timeout time.Duration) {
+	if worker.cmd.Process == nil {
+		return
 	}
 
-	if err := os.RemoveAll(inst.tempDir); err != nil {
-		fmt.Printf("Could not delete directory %s: %s\n", inst.tempDir, err)
+	pid := worker.cmd.Process.Pid
+	// Setpgid:true makes the worker its own process group leader, so -pid
+	// targets the whole group (the worker plus anything it spawned).
+	_ = syscall.Kill(-pid, syscall.SIGTERM)
+
+	select {
+	case <-worker.exited:
+		return
+	case <-time.After(timeout):
 	}
+
+	_ = syscall.Kill(-pid, syscall.SIGKILL)
+	<-worker.exited
 }
 
+// leastBusyWorker picks the worker with the fewest in-flight requests,
+// breaking ties round-robin so load spreads evenly when workers are idle.
+// Workers whose process has already exited are skipped in favor of any
+// still-live worker, since a dead worker's inflight count never moves and
+// would otherwise look perpetually idle. If every worker has exited, it
+// falls back to round-robin so the caller still gets a worker back (and
+// acquireWorker's own exited check can return ProcessExitedError).
+func (inst *DenoFunctionInstance) leastBusyWorker() *denoWorker {
+	n := len(inst.workers)
+	start := int(atomic.AddUint64(&inst.nextWorker, 1))
+
+	var best *denoWorker
+	var bestLoad int32
+	for i := 0; i < n; i++ {
+		worker := inst.workers[(start+i)%n]
+		if atomic.LoadInt32(&worker.dead) != 0 {
+			continue
+		}
+		if load := atomic.LoadInt32(&worker.inflight); best == nil || load < bestLoad {
+			best, bestLoad = worker, load
+		}
+	}
+	if best == nil {
+		best = inst.workers[start%n]
+	}
+	return best
+}
 
 var ProcessExitedError = errors.New("process exited")
 
-func (inst *DenoFunctionInstance) Invoke(ctx context.Context, event interface{}) (interface{}, error) {
-	type jsError struct {
-		Message string `json:"message"`
-		Stack   string `json:"stack"`
+// acquireWorker blocks until Invoke/InvokeStream may proceed: it rejects the
+// call if the instance is closing, bounds concurrency via inst.sem, and
+// picks a worker to dispatch to. The returned release func must be called
+// exactly once when the caller is done with the worker (for InvokeStream,
+// that's after the stream has been fully drained, not after the call
+// returns).
+func (inst *DenoFunctionInstance) acquireWorker(ctx context.Context) (*denoWorker, func(), error) {
+	inst.closingMu.Lock()
+	if atomic.LoadInt32(&inst.closing) != 0 {
+		inst.closingMu.Unlock()
+		return nil, nil, ErrClosing
+	}
+	inst.inflight.Add(1)
+	inst.closingMu.Unlock()
+
+	select {
+	case inst.sem <- struct{}{}:
+	case <-ctx.Done():
+		inst.inflight.Done()
+		return nil, nil, ctx.Err()
+	}
+
+	atomic.StoreInt64(&inst.lastInvokedNano, time.Now().UnixNano())
+
+	worker := inst.leastBusyWorker()
+	if worker.cmd.ProcessState != nil && worker.cmd.ProcessState.Exited() {
+		<-inst.sem
+		inst.inflight.Done()
+		return nil, nil, ProcessExitedError
 	}
+	atomic.AddInt32(&worker.inflight, 1)
 
-	// Instance can only be used sequentially for now
-	inst.runLock.Lock()
-	defer inst.runLock.Unlock()
+	release := func() {
+		atomic.AddInt32(&worker.inflight, -1)
+		<-inst.sem
+		inst.inflight.Done()
+	}
+	return worker, release, nil
+}
 
-	inst.lastInvoked = time.Now()
+// jsError is the shape of the {"error": {...}} envelope both the
+// non-streaming response body and each ndjson chunk use to report a
+// handler-thrown error.
+type jsError struct {
+	Message string `json:"message"`
+	Stack   string `json:"stack"`
+}
 
-	if inst.cmd.ProcessState != nil && inst.cmd.ProcessState.Exited() {
-		return nil, ProcessExitedError
+// asJSError checks whether a decoded JSON value is a {"error": {...}}
+// envelope and, if so, returns the error it represents. ok is false for any
+// other value, including a plain map that merely has no "error" key.
+func asJSError(value interface{}) (err error, ok bool) {
+	errorMap, isMap := value.(map[string]interface{})
+	if !isMap {
+		return nil, false
 	}
+	errorObj, hasError := errorMap["error"]
+	if !hasError {
+		return nil, false
+	}
+	var jsErr jsError
+	if unmarshalErr := json.Unmarshal([]byte(MustJsonString(errorObj)), &jsErr); unmarshalErr != nil {
+		return fmt.Errorf("Runtime error: %s", MustJsonString(errorObj)), true
+	}
+	return fmt.Errorf("Runtime error: %s\n%s", jsErr.Message, jsErr.Stack), true
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inst.serverURL, strings.NewReader(MustJsonString(event)))
+func (inst *DenoFunctionInstance) Invoke(ctx context.Context, event interface{}) (interface{}, error) {
+	if inst.config.Limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, inst.config.Limits.Timeout)
+		defer cancel()
+	}
+
+	worker, release, err := inst.acquireWorker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, worker.serverURL, strings.NewReader(MustJsonString(event)))
 	if err != nil {
 		return nil, errors.Wrap(err, "invoke call")
 	}
-	resp, err := http.DefaultClient.Do(req)
+	req.Header.Set(invocationIDHeader, newInvocationID())
+	resp, err := worker.client.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "function http request")
 	}
@@ -245,28 +631,109 @@ func (inst *DenoFunctionInstance) Invoke(ctx context.Context, event interface{})
 	if err := jsonDecoder.Decode(&result); err != nil {
 		return nil, errors.Wrap(err, "unmarshall response")
 	}
-	if errorMap, ok := result.(map[string]interface{}); ok {
-		if errorObj, ok := errorMap["error"]; ok {
-			var jsError jsError
-			err = json.Unmarshal([]byte(MustJsonString(errorObj)), &jsError)
-			if err != nil {
-				return nil, fmt.Errorf("Runtime error: %s", MustJsonString(errorObj))
-			}
-			return nil, fmt.Errorf("Runtime error: %s\n%s", jsError.Message, jsError.Stack)
-
-		}
+	if jsErr, ok := asJSError(result); ok {
+		return nil, jsErr
 	}
 
 	return result, nil
 }
 
-func pipeLogStreamToCallback(bufferedReader *bufio.Reader, callback func(message string)) {
-readLoop:
-	for {
-		line, err := bufferedReader.ReadString('\n')
-		if err != nil {
-			break readLoop
-		}
-		callback(line)
+// Chunk is one value produced by a streaming handler. Err is set only on
+// the final chunk delivered before the channel closes if the stream ended
+// abnormally; it is never set alongside a non-nil Value.
+type Chunk struct {
+	Value interface{}
+	Err   error
+}
+
+// InvokeStream is like Invoke, but for handlers that return an async
+// iterable instead of a single value: function_server.ts streams each
+// yielded value back as a newline-delimited JSON chunk, and this decodes
+// and forwards them onto the returned channel as they arrive rather than
+// buffering the whole response. The channel closes when the response body
+// ends; an error encountered mid-stream is delivered as a final
+// Chunk{Err: ...} before closing.
+func (inst *DenoFunctionInstance) InvokeStream(ctx context.Context, event interface{}) (<-chan Chunk, error) {
+	cancel := func() {}
+	if inst.config.Limits.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, inst.config.Limits.Timeout)
 	}
+
+	worker, release, err := inst.acquireWorker(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, worker.serverURL, strings.NewReader(MustJsonString(event)))
+	if err != nil {
+		release()
+		cancel()
+		return nil, errors.Wrap(err, "invoke call")
+	}
+	req.Header.Set("Accept", "application/x-ndjson")
+	req.Header.Set(invocationIDHeader, newInvocationID())
+
+	resp, err := worker.client.Do(req)
+	if err != nil {
+		release()
+		cancel()
+		return nil, errors.Wrap(err, "function http request")
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		release()
+		cancel()
+		return nil, fmt.Errorf("HTTP Error: %s", body)
+	}
+
+	chunks := make(chan Chunk)
+	go func() {
+		defer release()
+		defer cancel()
+		defer resp.Body.Close()
+		defer close(chunks)
+
+		// deliverErr hands the final error chunk to the consumer. A consumer
+		// already waiting on the channel gets it even once ctx is done (e.g.
+		// on Limits.Timeout); otherwise it gives up when ctx is done.
+		deliverErr := func(err error) {
+			select {
+			case chunks <- Chunk{Err: err}:
+				return
+			default:
+			}
+			select {
+			case chunks <- Chunk{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				if err != io.EOF {
+					deliverErr(errors.Wrap(err, "decode stream chunk"))
+				}
+				return
+			}
+			chunk := Chunk{Value: value}
+			if jsErr, ok := asJSError(value); ok {
+				chunk = Chunk{Err: jsErr}
+			}
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				deliverErr(errors.Wrap(ctx.Err(), "stream cut off"))
+				return
+			}
+			if chunk.Err != nil {
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
 }
@@ -0,0 +1,40 @@
+package denorunner
+
+import "testing"
+
+// TestLeastBusyWorkerStartsFromTieBreakIndex verifies leastBusyWorker
+// actually considers the worker at its round-robin start index, not just
+// every index after it: with loads [5, 5, 0, 5] and a start index of 2, the
+// idle worker at index 2 must win.
+func TestLeastBusyWorkerStartsFromTieBreakIndex(t *testing.T) {
+	workers := []*denoWorker{{inflight: 5}, {inflight: 5}, {inflight: 0}, {inflight: 5}}
+	inst := &DenoFunctionInstance{workers: workers, nextWorker: 1} // AddUint64 makes start == 2
+
+	if best := inst.leastBusyWorker(); best != workers[2] {
+		t.Fatalf("expected the idle worker at index 2, got a different worker")
+	}
+}
+
+// TestLeastBusyWorkerSkipsDeadWorkers verifies that a worker whose process
+// has already exited is never selected, even though its inflight count
+// never moves and would otherwise look idle.
+func TestLeastBusyWorkerSkipsDeadWorkers(t *testing.T) {
+	dead := &denoWorker{inflight: 0, dead: 1}
+	live := &denoWorker{inflight: 3}
+	inst := &DenoFunctionInstance{workers: []*denoWorker{dead, live}}
+
+	if best := inst.leastBusyWorker(); best != live {
+		t.Fatalf("expected the live worker to be picked over the dead one")
+	}
+}
+
+// TestLeastBusyWorkerFallsBackWhenAllDead verifies that leastBusyWorker
+// still returns a worker (rather than nil) when every worker has exited,
+// so acquireWorker's own exited check is what surfaces ProcessExitedError.
+func TestLeastBusyWorkerFallsBackWhenAllDead(t *testing.T) {
+	inst := &DenoFunctionInstance{workers: []*denoWorker{{dead: 1}, {dead: 1}}}
+
+	if best := inst.leastBusyWorker(); best == nil {
+		t.Fatal("expected a fallback worker, got nil")
+	}
+}
@@ -0,0 +1,36 @@
+//go:build linux
+
+package denorunner
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// applyResourceLimits bounds a worker's memory and CPU usage via
+// RLIMIT_AS/RLIMIT_CPU. exec.Cmd has no pre-exec hook for setting rlimits on
+// the child directly, so this re-execs the command through a shell that
+// applies them with ulimit before handing off to the real binary.
+func applyResourceLimits(cmd *exec.Cmd, limits Limits) {
+	if limits.MaxAddressSpaceMB <= 0 && limits.MaxCPUSeconds <= 0 {
+		return
+	}
+
+	var ulimits []string
+	if limits.MaxAddressSpaceMB > 0 {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -v %d", limits.MaxAddressSpaceMB*1024))
+	}
+	if limits.MaxCPUSeconds > 0 {
+		ulimits = append(ulimits, fmt.Sprintf("ulimit -t %d", limits.MaxCPUSeconds))
+	}
+
+	shPath, err := exec.LookPath("sh")
+	if err != nil {
+		shPath = "/bin/sh"
+	}
+
+	wrappedArgs := append([]string{cmd.Path}, cmd.Args[1:]...)
+	cmd.Args = append([]string{shPath, "-c", strings.Join(ulimits, "; ") + `; exec "$0" "$@"`}, wrappedArgs...)
+	cmd.Path = shPath
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package denorunner
+
+import "os/exec"
+
+// applyResourceLimits is a no-op outside Linux: RLIMIT_AS/RLIMIT_CPU
+// enforcement isn't implemented for other platforms.
+func applyResourceLimits(cmd *exec.Cmd, limits Limits) {}
@@ -4,9 +4,73 @@ import (
 	"context"
 	"denorunner"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
+// TestStructuredLogCapture verifies that console.log calls inside a handler
+// arrive via Config.LogHandler as parsed LogEvents (not just raw lines via
+// the legacy message callback), each tagged with the invocation ID of the
+// Invoke call that produced it.
+func TestStructuredLogCapture(t *testing.T) {
+	code := `
+function handle(event) {
+	console.log("handling", event.name);
+	return event;
+}
+`
+
+	var mu sync.Mutex
+	var events []denorunner.LogEvent
+
+	cfg := &denorunner.Config{
+		WorkDir:  ".",
+		DenoPath: "deno",
+		LogHandler: func(event denorunner.LogEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		},
+	}
+
+	ctx := context.Background()
+
+	fn, err := denorunner.NewDenoFunctionInstance(ctx, cfg, func(message string) {
+		fmt.Print(message)
+	}, struct{}{}, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fn.Close()
+
+	type data struct {
+		Name string `json:"name"`
+	}
+
+	if _, err := fn.Invoke(ctx, data{"Pete"}); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, event := range events {
+		if event.Level != "info" {
+			continue
+		}
+		if event.Message != "handling Pete" {
+			t.Fatalf("expected message %q, got %q", "handling Pete", event.Message)
+		}
+		if event.InvocationID == "" {
+			t.Fatal("expected a non-empty invocation ID on a log event produced during Invoke")
+		}
+		return
+	}
+	t.Fatal("expected at least one info-level log event from console.log")
+}
+
 func TestDenoBasic(t *testing.T) {
 	code := `
 function handle(event) {
@@ -112,3 +176,315 @@ function handle(event) {
 		}
 	}
 }
+
+// BenchmarkConcurrentInvoke fires 50 simultaneous Invoke calls at a single
+// instance to demonstrate that raising Config.Concurrency lets them actually
+// run in parallel instead of queueing behind a single worker.
+func BenchmarkConcurrentInvoke(b *testing.B) {
+	code := `
+function handle(event) {
+	return event;
+}
+`
+
+	cfg := &denorunner.Config{
+		WorkDir:     ".",
+		DenoPath:    "deno",
+		Concurrency: 8,
+	}
+
+	ctx := context.Background()
+
+	fn, err := denorunner.NewDenoFunctionInstance(ctx, cfg, func(message string) {
+		fmt.Print(message)
+	}, struct{}{}, code)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer fn.Close()
+
+	type data struct {
+		Name string `json:"name"`
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := fn.Invoke(ctx, data{"Pete"}); err != nil {
+					b.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// TestPermissionsDenyReadTextFile verifies that without an AllowReadPaths
+// entry covering the file, a handler's Deno.readTextFile call is rejected
+// rather than silently allowed (the old hard-coded flags had no
+// --allow-read at all, so this used to fail for a different reason: a
+// missing permission flag, not a denied one).
+func TestPermissionsDenyReadTextFile(t *testing.T) {
+	code := `
+async function handle(event) {
+	return await Deno.readTextFile("/etc/hostname");
+}
+`
+
+	cfg := &denorunner.Config{
+		WorkDir:  ".",
+		DenoPath: "deno",
+	}
+
+	ctx := context.Background()
+
+	fn, err := denorunner.NewDenoFunctionInstance(ctx, cfg, func(message string) {
+		fmt.Print(message)
+	}, struct{}{}, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fn.Close()
+
+	_, err = fn.Invoke(ctx, struct{}{})
+	if err == nil {
+		t.Fatal("expected Invoke to fail due to denied read permission")
+	}
+	if !strings.Contains(strings.ToLower(err.Error()), "permission") {
+		t.Fatalf("expected a permission error, got: %s", err)
+	}
+}
+
+// TestCloseRejectsNewInvokes verifies that once Close has been called,
+// further Invoke calls fail fast with ErrClosing instead of racing the
+// shutdown against a worker that's already being torn down.
+func TestCloseRejectsNewInvokes(t *testing.T) {
+	code := `
+function handle(event) {
+	return event;
+}
+`
+
+	cfg := &denorunner.Config{
+		WorkDir:  ".",
+		DenoPath: "deno",
+	}
+
+	ctx := context.Background()
+
+	fn, err := denorunner.NewDenoFunctionInstance(ctx, cfg, func(message string) {
+		fmt.Print(message)
+	}, struct{}{}, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fn.Close()
+
+	if _, err := fn.Invoke(ctx, struct{}{}); err != denorunner.ErrClosing {
+		t.Fatalf("expected ErrClosing, got: %v", err)
+	}
+}
+
+// TestCloseRaceWithInvoke fires many concurrent Invoke calls alongside
+// Close, guarding against a race where an Invoke call observes the
+// instance as not-yet-closing and registers itself with inst.inflight just
+// after Close's Wait has already returned: such a call would get dispatched
+// to a worker mid-SIGTERM/SIGKILL instead of ErrClosing. Every call must
+// come back with either a successful result or ErrClosing, never anything
+// else (e.g. a connection error from a half-killed worker).
+func TestCloseRaceWithInvoke(t *testing.T) {
+	code := `
+function handle(event) {
+	return event;
+}
+`
+
+	cfg := &denorunner.Config{
+		WorkDir:  ".",
+		DenoPath: "deno",
+	}
+
+	ctx := context.Background()
+
+	fn, err := denorunner.NewDenoFunctionInstance(ctx, cfg, func(message string) {
+		fmt.Print(message)
+	}, struct{}{}, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := fn.Invoke(ctx, struct{}{}); err != nil && err != denorunner.ErrClosing {
+				t.Errorf("expected success or ErrClosing, got: %v", err)
+			}
+		}()
+	}
+	fn.Close()
+	wg.Wait()
+}
+
+func TestInvokeStream(t *testing.T) {
+	code := `
+async function* handle(event) {
+	for (let i = 0; i < event.count; i++) {
+		yield { i };
+	}
+}
+`
+
+	cfg := &denorunner.Config{
+		WorkDir:  ".",
+		DenoPath: "deno",
+	}
+
+	ctx := context.Background()
+
+	fn, err := denorunner.NewDenoFunctionInstance(ctx, cfg, func(message string) {
+		fmt.Print(message)
+	}, struct{}{}, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fn.Close()
+
+	type data struct {
+		Count int `json:"count"`
+	}
+
+	chunks, err := fn.InvokeStream(ctx, data{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []interface{}
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			t.Fatal(chunk.Err)
+		}
+		got = append(got, chunk.Value)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("expected 3 streamed chunks, got %d", len(got))
+	}
+}
+
+// TestInvokeStreamErrorMidStream verifies that a handler throwing partway
+// through an async generator arrives as a final Chunk{Err: ...} rather than
+// being forwarded as a plain value (function_server.ts's streamBody wraps
+// it in an {"error": {...}} envelope, same as a non-streaming throw).
+func TestInvokeStreamErrorMidStream(t *testing.T) {
+	code := `
+async function* handle(event) {
+	for (let i = 0; i < event.count; i++) {
+		if (i === event.failAt) {
+			throw new Error("boom at " + i);
+		}
+		yield { i };
+	}
+}
+`
+
+	cfg := &denorunner.Config{
+		WorkDir:  ".",
+		DenoPath: "deno",
+	}
+
+	ctx := context.Background()
+
+	fn, err := denorunner.NewDenoFunctionInstance(ctx, cfg, func(message string) {
+		fmt.Print(message)
+	}, struct{}{}, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fn.Close()
+
+	type data struct {
+		Count  int `json:"count"`
+		FailAt int `json:"failAt"`
+	}
+
+	chunks, err := fn.InvokeStream(ctx, data{3, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []interface{}
+	var streamErr error
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+			continue
+		}
+		got = append(got, chunk.Value)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 successful chunks before the throw, got %d", len(got))
+	}
+	if streamErr == nil {
+		t.Fatal("expected a final Chunk{Err: ...} for the mid-stream throw")
+	}
+	if !strings.Contains(streamErr.Error(), "boom at 2") {
+		t.Fatalf("expected error to mention the handler's message, got: %s", streamErr)
+	}
+}
+
+// TestInvokeStreamRespectsTimeout verifies that Config.Limits.Timeout bounds
+// InvokeStream the same way it already bounds Invoke: a handler that never
+// finishes yielding must eventually surface a context-deadline error on the
+// stream instead of hanging forever.
+func TestInvokeStreamRespectsTimeout(t *testing.T) {
+	code := `
+async function* handle(event) {
+	while (true) {
+		yield { tick: true };
+		await new Promise((resolve) => setTimeout(resolve, 10));
+	}
+}
+`
+
+	cfg := &denorunner.Config{
+		WorkDir:  ".",
+		DenoPath: "deno",
+		Limits: denorunner.Limits{
+			Timeout: 100 * time.Millisecond,
+		},
+	}
+
+	ctx := context.Background()
+
+	fn, err := denorunner.NewDenoFunctionInstance(ctx, cfg, func(message string) {
+		fmt.Print(message)
+	}, struct{}{}, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fn.Close()
+
+	chunks, err := fn.InvokeStream(ctx, struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var streamErr error
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+		}
+	}
+
+	if streamErr == nil {
+		t.Fatal("expected the never-ending stream to be cut off by Limits.Timeout")
+	}
+}
@@ -0,0 +1,75 @@
+package denorunner
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// LogStream identifies which pipe a LogEvent came from.
+type LogStream string
+
+const (
+	LogStreamStdout LogStream = "stdout"
+	LogStreamStderr LogStream = "stderr"
+)
+
+// LogEvent is one line of output from a worker process. Message/Level/Fields
+// come from parsing the ndjson envelope function_server.ts emits for
+// console.log/warn/error calls; a line that doesn't parse as one falls back
+// to Level "raw" with the line verbatim as Message.
+type LogEvent struct {
+	Time         time.Time
+	Stream       LogStream
+	Level        string
+	Message      string
+	Fields       map[string]interface{}
+	InvocationID string
+}
+
+// logEnvelope is the ndjson shape the console overrides in
+// function_server.ts emit: {"lvl":"info","msg":"...","fields":{...},"invocationId":"..."}
+type logEnvelope struct {
+	Level        string                 `json:"lvl"`
+	Message      string                 `json:"msg"`
+	Fields       map[string]interface{} `json:"fields"`
+	InvocationID string                 `json:"invocationId"`
+}
+
+func parseLogLine(stream LogStream, line string) LogEvent {
+	line = strings.TrimRight(line, "\r\n")
+
+	var env logEnvelope
+	if err := json.Unmarshal([]byte(line), &env); err == nil && env.Level != "" {
+		return LogEvent{
+			Time:         time.Now(),
+			Stream:       stream,
+			Level:        env.Level,
+			Message:      env.Message,
+			Fields:       env.Fields,
+			InvocationID: env.InvocationID,
+		}
+	}
+
+	return LogEvent{
+		Time:    time.Now(),
+		Stream:  stream,
+		Level:   "raw",
+		Message: line,
+	}
+}
+
+// pipeLogStream reads newline-delimited output from a worker pipe, parses
+// each line into a LogEvent, and hands it to handler.
+func pipeLogStream(stream LogStream, bufferedReader *bufio.Reader, handler func(LogEvent)) {
+	for {
+		line, err := bufferedReader.ReadString('\n')
+		if line != "" {
+			handler(parseLogLine(stream, line))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
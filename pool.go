@@ -0,0 +1,269 @@
+package denorunner
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultIdleTTL      = 5 * time.Minute
+	defaultReapInterval = 30 * time.Second
+)
+
+// poolKey identifies a cached instance by the content hash of its code and
+// its init data, matching how NewDenoFunctionInstance already derives
+// function-<hash> directories.
+type poolKey struct {
+	functionHash functionHash
+	initDataHash string
+}
+
+func newPoolKey(code string, initData interface{}) poolKey {
+	h := sha1.New()
+	h.Write(MustJsonByteSlice(initData))
+	return poolKey{
+		functionHash: newFunctionHash(code),
+		initDataHash: fmt.Sprintf("%x", h.Sum(nil)),
+	}
+}
+
+// PoolConfig configures a Pool. Config is embedded and passed through to
+// every NewDenoFunctionInstance the pool creates.
+type PoolConfig struct {
+	Config
+
+	// IdleTTL is how long an instance may go without an Invoke before the
+	// reaper evicts it. Defaults to 5 minutes.
+	IdleTTL time.Duration
+
+	// MaxInstances caps the number of live instances; once exceeded, the
+	// least-recently-invoked instance is evicted to make room. 0 means
+	// unlimited.
+	MaxInstances int
+
+	// ReapInterval is how often the background reaper checks for idle
+	// instances. Defaults to 30 seconds.
+	ReapInterval time.Duration
+
+	// LogCallback receives log lines from every instance the pool creates.
+	// Named distinctly from the embedded Config.LogHandler (structured
+	// LogEvents) so the two don't collide and silently shadow each other.
+	LogCallback func(message string)
+}
+
+// Pool owns a set of DenoFunctionInstances keyed by (functionHash,
+// initDataHash), creating them on demand and reusing them across calls so
+// callers don't have to manage process lifecycle themselves.
+type Pool struct {
+	config       Config
+	idleTTL      time.Duration
+	maxInstances int
+	logCallback  func(message string)
+
+	mu        sync.Mutex
+	instances map[poolKey]*DenoFunctionInstance
+	lru       []poolKey // least-recently-used first
+
+	evictedCount int64
+
+	stopReaper chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewPool creates a Pool and starts its background idle reaper.
+func NewPool(cfg PoolConfig) *Pool {
+	idleTTL := cfg.IdleTTL
+	if idleTTL <= 0 {
+		idleTTL = defaultIdleTTL
+	}
+	reapInterval := cfg.ReapInterval
+	if reapInterval <= 0 {
+		reapInterval = defaultReapInterval
+	}
+	logCallback := cfg.LogCallback
+	if logCallback == nil {
+		logCallback = func(message string) {}
+	}
+
+	p := &Pool{
+		config:       cfg.Config,
+		idleTTL:      idleTTL,
+		maxInstances: cfg.MaxInstances,
+		logCallback:  logCallback,
+		instances:    map[poolKey]*DenoFunctionInstance{},
+		stopReaper:   make(chan struct{}),
+	}
+
+	go p.reapLoop(reapInterval)
+
+	return p
+}
+
+// Invoke looks up (or creates) the instance for code+initData and invokes
+// it with event. This is the primary entry point into a Pool.
+func (p *Pool) Invoke(ctx context.Context, code string, initData interface{}, event interface{}) (interface{}, error) {
+	inst, err := p.getOrCreate(ctx, code, initData)
+	if err != nil {
+		return nil, err
+	}
+	return inst.Invoke(ctx, event)
+}
+
+func (p *Pool) getOrCreate(ctx context.Context, code string, initData interface{}) (*DenoFunctionInstance, error) {
+	key := newPoolKey(code, initData)
+
+	p.mu.Lock()
+	if inst, ok := p.instances[key]; ok {
+		p.touchLocked(key)
+		p.mu.Unlock()
+		return inst, nil
+	}
+	p.mu.Unlock()
+
+	// Spin up the instance outside the lock: this can take a while
+	// (starting Deno subprocesses), and we don't want to block lookups for
+	// unrelated keys while it happens.
+	inst, err := NewDenoFunctionInstance(ctx, &p.config, p.logCallback, initData, code)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, ok := p.instances[key]; ok {
+		// Another caller raced us and won; use theirs, discard ours.
+		inst.Close()
+		p.touchLocked(key)
+		return existing, nil
+	}
+
+	p.instances[key] = inst
+	p.lru = append(p.lru, key)
+	p.evictOverCapacityLocked()
+
+	return inst, nil
+}
+
+// touchLocked moves key to the most-recently-used end of the LRU list.
+func (p *Pool) touchLocked(key poolKey) {
+	for i, k := range p.lru {
+		if k == key {
+			p.lru = append(p.lru[:i], p.lru[i+1:]...)
+			break
+		}
+	}
+	p.lru = append(p.lru, key)
+}
+
+// evictOverCapacityLocked closes and removes least-recently-used instances
+// until the pool is back within MaxInstances.
+func (p *Pool) evictOverCapacityLocked() {
+	if p.maxInstances <= 0 {
+		return
+	}
+	for len(p.lru) > p.maxInstances {
+		key := p.lru[0]
+		p.lru = p.lru[1:]
+		if inst, ok := p.instances[key]; ok {
+			delete(p.instances, key)
+			atomic.AddInt64(&p.evictedCount, 1)
+			inst.Close()
+		}
+	}
+}
+
+func (p *Pool) reapLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopReaper:
+			return
+		case <-ticker.C:
+			p.reapIdle()
+		}
+	}
+}
+
+func (p *Pool) reapIdle() {
+	p.mu.Lock()
+	var toClose []*DenoFunctionInstance
+	for key, inst := range p.instances {
+		if time.Since(inst.LastInvoked()) < p.idleTTL {
+			continue
+		}
+		delete(p.instances, key)
+		for i, k := range p.lru {
+			if k == key {
+				p.lru = append(p.lru[:i], p.lru[i+1:]...)
+				break
+			}
+		}
+		atomic.AddInt64(&p.evictedCount, 1)
+		toClose = append(toClose, inst)
+	}
+	p.mu.Unlock()
+
+	for _, inst := range toClose {
+		inst.Close()
+	}
+}
+
+// PoolStats reports the current state of a Pool for observability.
+type PoolStats struct {
+	Live    int
+	Idle    int
+	Evicted int64
+}
+
+// Stats returns a snapshot of the pool's live/idle/evicted instance counts.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	idle := 0
+	for _, inst := range p.instances {
+		if time.Since(inst.LastInvoked()) >= p.idleTTL {
+			idle++
+		}
+	}
+
+	return PoolStats{
+		Live:    len(p.instances),
+		Idle:    idle,
+		Evicted: atomic.LoadInt64(&p.evictedCount),
+	}
+}
+
+// Close stops the reaper and drains every instance currently held by the
+// pool, giving each one a chance to shut down cleanly (see
+// DenoFunctionInstance.Close). It blocks until all of them are done.
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.stopReaper)
+
+		p.mu.Lock()
+		instances := make([]*DenoFunctionInstance, 0, len(p.instances))
+		for _, inst := range p.instances {
+			instances = append(instances, inst)
+		}
+		p.instances = map[poolKey]*DenoFunctionInstance{}
+		p.lru = nil
+		p.mu.Unlock()
+
+		var wg sync.WaitGroup
+		for _, inst := range instances {
+			inst := inst
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				inst.Close()
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,77 @@
+package denorunner_test
+
+import (
+	"context"
+	"denorunner"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPoolReusesInstance(t *testing.T) {
+	code := `
+function handle(event) {
+	return event;
+}
+`
+
+	pool := denorunner.NewPool(denorunner.PoolConfig{
+		Config: denorunner.Config{
+			WorkDir:  ".",
+			DenoPath: "deno",
+		},
+	})
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	type data struct {
+		Name string `json:"name"`
+	}
+
+	if _, err := pool.Invoke(ctx, code, struct{}{}, data{"Pete"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Invoke(ctx, code, struct{}{}, data{"Pete"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := pool.Stats()
+	if stats.Live != 1 {
+		t.Fatalf("expected 1 live instance after two calls with the same code+init, got %d", stats.Live)
+	}
+}
+
+func TestPoolEvictsIdleInstances(t *testing.T) {
+	code := `
+function handle(event) {
+	return event;
+}
+`
+
+	pool := denorunner.NewPool(denorunner.PoolConfig{
+		Config: denorunner.Config{
+			WorkDir:  ".",
+			DenoPath: "deno",
+		},
+		IdleTTL:      50 * time.Millisecond,
+		ReapInterval: 20 * time.Millisecond,
+	})
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	if _, err := pool.Invoke(ctx, code, struct{}{}, struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if pool.Stats().Evicted > 0 {
+			fmt.Println("instance evicted as expected")
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("expected idle instance to be evicted")
+}
@@ -0,0 +1,26 @@
+package denorunner
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// InstallSignalHandler traps SIGINT/SIGTERM and drains pool (stopping the
+// reaper and gracefully closing every instance it holds) so a process
+// embedding a Pool gets clean shutdown for free. It returns immediately;
+// the handler runs in the background and fires at most once. Once the pool
+// has drained, it calls os.Exit(0) itself: signal.Notify disables Go's
+// default terminate-on-signal behavior for the signals it traps, so without
+// this the process would otherwise keep running after SIGINT/SIGTERM
+// instead of exiting.
+func InstallSignalHandler(pool *Pool) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		pool.Close()
+		os.Exit(0)
+	}()
+}
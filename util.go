@@ -8,21 +8,23 @@ import (
 	"time"
 )
 
-var handedOutPorts = map[int]bool{}
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
 
+// FindFreePort probes randomly chosen TCP ports on loopback within
+// [startPort, startPort+10000) until one is free, returning -1 after about
+// 1000 failed tries. Only used by the "tcp" transport; the default "unix"
+// transport has no port to find.
 func FindFreePort(startPort int) int {
-	rand.Seed(time.Now().UnixNano())
 	port := startPort + rand.Intn(10000)
 
 	iterations := 0
 	for {
-		if !handedOutPorts[port] {
-			l, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", port))
-			if err == nil {
-				l.Close()
-				handedOutPorts[port] = true
-				return port
-			}
+		l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err == nil {
+			l.Close()
+			return port
 		}
 		port = startPort + rand.Intn(10000)
 		iterations++